@@ -0,0 +1,146 @@
+package rtns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AnnounceBackoff is the base delay between retries against an unresponsive
+// direct announce endpoint; it is multiplied by the attempt number.
+const AnnounceBackoff = 500 * time.Millisecond
+
+// AnnounceRetries is how many attempts flushTo makes against a failing
+// endpoint, the first try included, before giving up on it for the current
+// flush.
+const AnnounceRetries = 3
+
+// announceRecord is one IPNS record queued for direct HTTP announcement.
+type announceRecord struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// announcer batches freshly published IPNS records and pushes them directly
+// to a configurable set of HTTP endpoints, shortcutting DHT propagation
+// latency for operators running known reprovider/indexer nodes - useful when
+// rtns is deployed behind a private-network Config.Secret where the public
+// DHT can't be used for discovery at all.
+type announcer struct {
+	endpoints []string
+	client    *http.Client
+
+	mu      sync.Mutex
+	pending []announceRecord
+	// retry holds, per endpoint, the batch that endpoint still hasn't
+	// acknowledged. It's kept separate per endpoint so a single down
+	// endpoint only ever retries its own backlog instead of every
+	// endpoint resending records the healthy ones already have.
+	retry map[string][]announceRecord
+}
+
+func newAnnouncer(endpoints []string) *announcer {
+	return &announcer{
+		endpoints: endpoints,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		retry:     make(map[string][]announceRecord),
+	}
+}
+
+// queue adds a record to the next flush. It is a no-op when no direct
+// announce endpoints are configured.
+func (a *announcer) queue(key string, value []byte) {
+	if len(a.endpoints) == 0 {
+		return
+	}
+	a.mu.Lock()
+	a.pending = append(a.pending, announceRecord{Key: key, Value: value})
+	a.mu.Unlock()
+}
+
+// flush POSTs every queued record to each configured endpoint, retrying with
+// backoff on failure. Endpoints are tracked independently: a record that an
+// endpoint never acknowledges is retried against only that endpoint on the
+// next flush, rather than being resent to endpoints that already have it.
+// The first error encountered is returned once every endpoint has been
+// tried.
+func (a *announcer) flush(ctx context.Context) error {
+	if len(a.endpoints) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	fresh := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+
+	var firstErr error
+	for _, endpoint := range a.endpoints {
+		a.mu.Lock()
+		batch := append(a.retry[endpoint], fresh...)
+		a.mu.Unlock()
+		if len(batch) == 0 {
+			continue
+		}
+
+		body, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+
+		if err := a.flushTo(ctx, endpoint, body); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			a.mu.Lock()
+			a.retry[endpoint] = batch
+			a.mu.Unlock()
+			continue
+		}
+
+		a.mu.Lock()
+		delete(a.retry, endpoint)
+		a.mu.Unlock()
+	}
+	return firstErr
+}
+
+func (a *announcer) flushTo(ctx context.Context, endpoint string, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt < AnnounceRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(AnnounceBackoff * time.Duration(attempt)):
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := a.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			lastErr = fmt.Errorf("direct announce to %s failed: %s", endpoint, resp.Status)
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// Flush immediately pushes any queued records to the configured direct
+// announce endpoints, rather than waiting for the background flush kicked
+// off by the next publish.
+func (r *rtns) Flush(ctx context.Context) error {
+	return r.announcer.flush(ctx)
+}
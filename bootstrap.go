@@ -0,0 +1,24 @@
+package rtns
+
+import (
+	"fmt"
+
+	lp "github.com/RTradeLtd/rtns/internal/libp2p"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+// DefaultBootstrap connects to the standard public IPFS bootstrap peers.
+func (r *rtns) DefaultBootstrap() {
+	r.Bootstrap(lp.DefaultBootstrapPeers())
+}
+
+// Bootstrap connects the host to peers, seeding the DHT's routing table. A
+// peer that can't be dialed is logged and skipped rather than aborting the
+// rest of the list.
+func (r *rtns) Bootstrap(peers []peer.AddrInfo) {
+	for _, pi := range peers {
+		if err := r.h.Connect(r.ctx, pi); err != nil {
+			fmt.Println("error bootstrapping peer:", pi.ID.String(), err.Error())
+		}
+	}
+}
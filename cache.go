@@ -0,0 +1,34 @@
+package rtns
+
+import "sync"
+
+// Cache tracks the key names rtns has locally published (or learned about
+// from the keystore), so the republisher knows what to refresh without
+// every caller threading that state through separately.
+type Cache struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{keys: make(map[string]struct{})}
+}
+
+// Set records name as known.
+func (c *Cache) Set(name string) {
+	c.mu.Lock()
+	c.keys[name] = struct{}{}
+	c.mu.Unlock()
+}
+
+// List returns every name currently known to the cache.
+func (c *Cache) List() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.keys))
+	for name := range c.keys {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,20 @@
+package rtns
+
+import (
+	"github.com/ipfs/go-datastore"
+	badger "github.com/ipfs/go-ds-badger"
+	leveldb "github.com/ipfs/go-ds-leveldb"
+)
+
+// NewBadgerDatastore opens (or creates) a badger-backed datastore at path,
+// suitable for Config.Datastore. Unlike the default in-memory
+// MapDatastore, records written here survive a process restart.
+func NewBadgerDatastore(path string) (datastore.Batching, error) {
+	return badger.NewDatastore(path, nil)
+}
+
+// NewLevelDBDatastore opens (or creates) a leveldb-backed datastore at
+// path, suitable for Config.Datastore.
+func NewLevelDBDatastore(path string) (datastore.Batching, error) {
+	return leveldb.NewDatastore(path, nil)
+}
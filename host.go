@@ -10,11 +10,13 @@ import (
 	"github.com/ipfs/go-datastore"
 	dssync "github.com/ipfs/go-datastore/sync"
 	"github.com/ipfs/go-ipfs/namesys"
-	"github.com/ipfs/go-path"
-	ci "github.com/libp2p/go-libp2p-crypto"
-	host "github.com/libp2p/go-libp2p-host"
+	ipns "github.com/ipfs/go-ipns"
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	host "github.com/libp2p/go-libp2p-core/host"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	peerstore "github.com/libp2p/go-libp2p-core/peerstore"
+	record "github.com/libp2p/go-libp2p-core/record"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
-	peerstore "github.com/libp2p/go-libp2p-peerstore"
 	"github.com/libp2p/go-libp2p-peerstore/pstoremem"
 	"github.com/multiformats/go-multiaddr"
 )
@@ -25,11 +27,19 @@ type Service interface {
 	// service management
 	Close()
 	DefaultBootstrap()
-	Bootstrap(peers []peerstore.PeerInfo)
+	Bootstrap(peers []peer.AddrInfo)
 	// record publishing
 	Publish(ctx context.Context, pk ci.PrivKey, cache bool, keyID, content string) error
 	PublishWithEOL(ctx context.Context, pk ci.PrivKey, eol time.Time, cache bool, keyID, content string) error
 
+	// record resolution
+	Resolve(ctx context.Context, name string, opts ...ResolveOption) (string, error)
+	ResolveAsync(ctx context.Context, name string, opts ...ResolveOption) <-chan ResolveResult
+
+	// Flush pushes any records queued for direct HTTP announcement
+	// immediately, rather than waiting for the next publish to trigger it.
+	Flush(ctx context.Context) error
+
 	// key management
 	GetKey(name string) (ci.PrivKey, error)
 	HasKey(name string) (bool, error)
@@ -41,21 +51,69 @@ type Config struct {
 	PK          ci.PrivKey
 	ListenAddrs []multiaddr.Multiaddr
 	Secret      []byte
+
+	// Validator overrides the record validation logic applied to IPNS
+	// records accepted from the DHT. It defaults to ipns.Validator{KeyBook:
+	// <peerstore>}, the same validator the public IPNS network uses; set it
+	// when running a private network (alongside Secret) that needs its own
+	// record validation rules.
+	Validator record.Validator
+
+	// DefaultTTL is written into every record's TTL field, advising
+	// resolvers how long they may cache it before checking for a newer
+	// sequence number. Defaults to DefaultRecordTTL.
+	DefaultTTL time.Duration
+
+	// AddrFilters are whyrusleeping/multiaddr-filter masks (eg.
+	// "/ip4/10.0.0.0/ipcidr/8") handed to libp2p.FilterAddresses. The
+	// addresses we advertise are not checked against them; use
+	// NoAnnounceAddrs to keep an address out of what we advertise.
+	AddrFilters []string
+
+	// AnnounceAddrs overrides the addresses we advertise to the network,
+	// regardless of what we're actually listening on. Leave nil to announce
+	// our listen addresses as-is.
+	AnnounceAddrs []multiaddr.Multiaddr
+
+	// NoAnnounceAddrs are subtracted from the addresses we advertise, even
+	// when we're listening on them (eg. container-internal addresses).
+	NoAnnounceAddrs []multiaddr.Multiaddr
+
+	// ConnMgr bounds how many peer connections our host keeps open. A zero
+	// value disables the connection manager.
+	ConnMgr struct {
+		LowWater    int
+		HighWater   int
+		GracePeriod time.Duration
+	}
+
+	// DirectAnnounce lists HTTP endpoints that freshly published IPNS
+	// records are POSTed to directly, in addition to the DHT. Leave nil to
+	// disable direct announcement.
+	DirectAnnounce []string
+
+	// Datastore backs all locally stored IPNS records and republisher
+	// state. Defaults to an in-memory MapDatastore, which does not survive
+	// a process restart - use NewBadgerDatastore or NewLevelDBDatastore
+	// with DSPath for a backend that does.
+	Datastore datastore.Batching
 }
 
 // rtns manages all the needed components
 // to interact with public and private IPNS
 // networks.
 type rtns struct {
-	h     host.Host
-	pk    ci.PrivKey
-	d     *dht.IpfsDHT
-	ds    datastore.Datastore
-	ns    namesys.NameSystem
-	ps    peerstore.Peerstore
-	ctx   context.Context
-	keys  *RKeystore
-	cache *Cache
+	h         host.Host
+	pk        ci.PrivKey
+	d         *dht.IpfsDHT
+	ds        datastore.Datastore
+	ns        namesys.NameSystem
+	ps        peerstore.Peerstore
+	ctx       context.Context
+	keys      *RKeystore
+	cache     *Cache
+	cfg       Config
+	announcer *announcer
 }
 
 // NewService is used to instantiate an RTNS publisher service
@@ -66,28 +124,43 @@ func NewService(ctx context.Context, kbClient *kaas.Client, cfg Config) (Service
 // NewRTNS is used to instantiate our RTNS service, and start the republisher
 // intended to be used as a `Publisher` type by external libraries
 func newRTNS(ctx context.Context, kbClient *kaas.Client, cfg Config) (*rtns, error) {
-	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	ds := cfg.Datastore
+	if ds == nil {
+		ds = dssync.MutexWrap(datastore.NewMapDatastore())
+	}
 	ps := pstoremem.NewPeerstore()
-	ht, dt, err := lp.SetupLibp2p(ctx, cfg.PK, cfg.Secret, cfg.ListenAddrs, ps, ds)
+	validator := cfg.Validator
+	if validator == nil {
+		validator = ipns.Validator{KeyBook: ps}
+	}
+	ht, dt, err := lp.SetupLibp2p(
+		ctx, cfg.PK, cfg.Secret, cfg.ListenAddrs, ps, ds, validator,
+		cfg.AddrFilters, cfg.AnnounceAddrs, cfg.NoAnnounceAddrs, cfg.ConnMgr,
+	)
 	if err != nil {
 		return nil, err
 	}
 	r := &rtns{
-		h:     ht,
-		d:     dt,
-		pk:    cfg.PK,
-		ds:    ds,
-		ps:    ps,
-		ns:    namesys.NewNameSystem(dt, ds, 128),
-		ctx:   ctx,
-		keys:  NewRKeystore(ctx, kbClient),
-		cache: NewCache(),
+		h:         ht,
+		d:         dt,
+		pk:        cfg.PK,
+		ds:        ds,
+		ps:        ps,
+		ns:        namesys.NewNameSystem(dt, ds, 128),
+		ctx:       ctx,
+		keys:      NewRKeystore(ctx, kbClient),
+		cache:     NewCache(),
+		cfg:       cfg,
+		announcer: newAnnouncer(cfg.DirectAnnounce),
 	}
 	go r.startRepublisher()
 	return r, nil
 }
 
-// Close is used to close all service needed by our publisher
+// Close is used to close all service needed by our publisher. For a
+// persistent Config.Datastore (see NewBadgerDatastore, NewLevelDBDatastore)
+// this is also what flushes outstanding writes to disk, so records aren't
+// lost on an unclean restart.
 func (r *rtns) Close() {
 	if err := r.d.Close(); err != nil {
 		fmt.Println("error shutting down dht:", err.Error())
@@ -102,10 +175,7 @@ func (r *rtns) Close() {
 
 // Publish is used to publish content with a fixed lifetime and ttl
 func (r *rtns) Publish(ctx context.Context, pk ci.PrivKey, cache bool, keyID, content string) error {
-	if cache {
-		r.cache.Set(keyID)
-	}
-	return r.ns.Publish(ctx, pk, path.FromString(content))
+	return r.PublishWithEOL(ctx, pk, time.Now().Add(RecordLifetime), cache, keyID, content)
 }
 
 // PublishWithEOL is used to publish an IPNS record with non default lifetime values
@@ -113,7 +183,7 @@ func (r *rtns) PublishWithEOL(ctx context.Context, pk ci.PrivKey, eol time.Time,
 	if cache {
 		r.cache.Set(keyID)
 	}
-	return r.ns.PublishWithEOL(ctx, pk, path.FromString(content), eol)
+	return r.publishRecord(ctx, pk, content, eol)
 }
 
 // GetKey is used to retrieve a key from the
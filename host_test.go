@@ -3,105 +3,62 @@ package rtns
 import (
 	"context"
 	"errors"
-	"fmt"
 	"reflect"
 	"testing"
 
-	cfg "github.com/RTradeLtd/config/v2"
 	pb "github.com/RTradeLtd/grpc/krab"
 	kaas "github.com/RTradeLtd/kaas/v2"
-	lp "github.com/RTradeLtd/rtns/internal/libp2p"
 	"github.com/RTradeLtd/rtns/mocks"
-	crypto "github.com/libp2p/go-libp2p-crypto"
-	peer "github.com/libp2p/go-libp2p-peer"
-	"github.com/multiformats/go-multiaddr"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
 )
 
-var (
-	ipfsPath1 = "/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
-	ipfsPath2 = "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
-)
+var ipfsPath1 = "/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
 
 // TODO:
 // we need to configure fakes for the krab client
 // so that we may spoof a valid krab backend
 
-func Test_New_Publisher(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	//////////////////
-	// setup mocks //
-	////////////////
-
-	pk1 := newPK(t)
-	pk1Bytes, err := pk1.Bytes()
-	if err != nil {
-		t.Fatal(err)
-	}
-	pk2 := newPK(t)
-	pk2Bytes, err := pk2.Bytes()
-	if err != nil {
-		t.Fatal(err)
+func Test_RepublishEntries_NoCachedKeys(t *testing.T) {
+	ctx := context.Background()
+	r := &rtns{
+		ctx:   ctx,
+		cache: NewCache(),
+		keys:  NewRKeystore(ctx, &kaas.Client{ServiceClient: &mocks.FakeServiceClient{}}),
 	}
-	fkb := &mocks.FakeServiceClient{}
-	fkb.GetPrivateKeyReturnsOnCall(0, &pb.Response{Status: "Ok", PrivateKey: pk1Bytes}, nil)
-	fkb.GetPrivateKeyReturnsOnCall(1, &pb.Response{Status: "Ok", PrivateKey: pk2Bytes}, nil)
-
-	fns := &mocks.FakeNameSystem{}
-	fns.PublishReturnsOnCall(0, nil)
-	fns.PublishReturnsOnCall(1, nil)
-	fns.PublishReturnsOnCall(2, errors.New("publish failed"))
-
-	//////////////////////
-	// setup publisher //
-	////////////////////
-
-	rtns := newRTNS(ctx, t, fkb, fns)
-	defer rtns.Close()
-	rtns.Bootstrap(lp.DefaultBootstrapPeers())
-
-	//////////////////
-	// start tests //
-	////////////////
 
-	// ensure no previous records have been published
-	if err := rtns.republishEntries(); err != errNoRecordsPublisher {
+	if err := r.republishEntries(); err != errNoRecordsPublisher {
 		t.Fatal("wrong error received")
 	}
+}
 
-	if err := rtns.Publish(ctx, pk1, "pk1", ipfsPath1); err != nil {
-		t.Fatal(err)
-	}
-	if len(rtns.cache.List()) != 1 {
-		fmt.Println("cache length:", len(rtns.cache.List()))
-		t.Fatal("invalid cache length")
-	}
-	pid, err := peer.IDFromPublicKey(pk1.GetPublic())
+func Test_RepublishEntries_SkipsFailingKeys(t *testing.T) {
+	ctx := context.Background()
+	goodPK := newPK(t)
+	goodPKBytes, err := goodPK.Bytes()
 	if err != nil {
 		t.Fatal(err)
 	}
-	fmt.Println("pk1", pid.String())
 
-	if err := rtns.Publish(ctx, pk2, "pk2", ipfsPath2); err != nil {
-		t.Fatal(err)
-	}
-	if len(rtns.cache.List()) != 2 {
-		fmt.Println("cache length:", len(rtns.cache.List()))
-		t.Fatal("invalid cache length")
-	}
-	pid, err = peer.IDFromPublicKey(pk2.GetPublic())
-	if err != nil {
-		t.Fatal(err)
-	}
-	fmt.Println("pk2", pid.String())
+	fkb := &mocks.FakeServiceClient{}
+	fkb.GetPrivateKeyReturnsOnCall(0, &pb.Response{Status: "BAD"}, errors.New("key rotated"))
+	fkb.GetPrivateKeyReturnsOnCall(1, &pb.Response{Status: "OK", PrivateKey: goodPKBytes}, nil)
 
-	if err := rtns.republishEntries(); err != nil {
-		t.Fatal(err)
+	r := &rtns{
+		ctx:   ctx,
+		ds:    dssync.MutexWrap(datastore.NewMapDatastore()),
+		cache: NewCache(),
+		keys:  NewRKeystore(ctx, &kaas.Client{ServiceClient: fkb}),
 	}
+	r.cache.Set("bad")
+	r.cache.Set("good")
 
-	if err := rtns.Publish(ctx, pk2, "pk2", ipfsPath2); err == nil {
-		t.Fatal("error expected")
+	// Whichever key is looked up first fails to load (call 0 is stubbed with
+	// an error) and the other has no local entry yet (ds is empty); neither
+	// should abort the other.
+	if err := r.republishEntries(); err != nil {
+		t.Fatal("a single failing key should not abort the batch:", err)
 	}
 }
 
@@ -163,20 +120,6 @@ func Test_Keystore(t *testing.T) {
 	}
 }
 
-func newRTNS(ctx context.Context, t *testing.T, fkb *mocks.FakeServiceClient, fns *mocks.FakeNameSystem) *RTNS {
-	pk := newPK(t)
-	addr, err := multiaddr.NewMultiaddr("/ip4/0.0.0.0/tcp/4005")
-	if err != nil {
-		t.Fatal(err)
-	}
-	rtns, err := NewRTNS(ctx, newServicesConfig(), "test", pk, []multiaddr.Multiaddr{addr})
-	if err != nil {
-		t.Fatal(err)
-	}
-	rtns.keys.kb = &kaas.Client{ServiceClient: fkb}
-	rtns.ns = fns
-	return rtns
-}
 func newPK(t *testing.T) crypto.PrivKey {
 	pk, _, err := crypto.GenerateKeyPair(crypto.ECDSA, 2048)
 	if err != nil {
@@ -184,7 +127,3 @@ func newPK(t *testing.T) crypto.PrivKey {
 	}
 	return pk
 }
-
-func newServicesConfig() cfg.Services {
-	return cfg.Services{}
-}
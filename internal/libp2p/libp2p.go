@@ -0,0 +1,144 @@
+// Package libp2p sets up the libp2p host and DHT that rtns publishes and
+// resolves IPNS records over.
+package libp2p
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	libp2p "github.com/libp2p/go-libp2p"
+	connmgr "github.com/libp2p/go-libp2p/p2p/net/connmgr"
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	host "github.com/libp2p/go-libp2p-core/host"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	peerstore "github.com/libp2p/go-libp2p-core/peerstore"
+	record "github.com/libp2p/go-libp2p-core/record"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	pnet "github.com/libp2p/go-libp2p-pnet"
+	multiaddr "github.com/multiformats/go-multiaddr"
+	maskfilter "github.com/whyrusleeping/multiaddr-filter"
+)
+
+// defaultBootstrapAddrs are the standard public IPFS bootstrap nodes.
+var defaultBootstrapAddrs = []string{
+	"/dnsaddr/bootstrap.libp2p.io/p2p/QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN",
+	"/dnsaddr/bootstrap.libp2p.io/p2p/QmQCU2EcMqAqQPR2i9bChDtGNJchTbq5TbXJJ16u19uLTa",
+	"/dnsaddr/bootstrap.libp2p.io/p2p/QmbLHAnMoJPWSCR5Zhtx6BHJX9KiKNN6tpvbUcqanj75Nb",
+	"/dnsaddr/bootstrap.libp2p.io/p2p/QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt",
+}
+
+// SetupLibp2p builds the libp2p host and Kademlia DHT rtns runs on top of.
+// addrFilters are passed to libp2p.FilterAddresses as masks (advertised
+// addresses are not checked against them), addrsFactory
+// is built from announceAddrs/noAnnounceAddrs to override what gets
+// advertised, secret (when set) wraps the swarm in a private network, and
+// connMgr (when its HighWater is non-zero) bounds how many peer connections
+// the host keeps open.
+func SetupLibp2p(
+	ctx context.Context,
+	pk ci.PrivKey,
+	secret []byte,
+	listenAddrs []multiaddr.Multiaddr,
+	ps peerstore.Peerstore,
+	ds datastore.Batching,
+	validator record.Validator,
+	addrFilters []string,
+	announceAddrs []multiaddr.Multiaddr,
+	noAnnounceAddrs []multiaddr.Multiaddr,
+	connMgr struct {
+		LowWater    int
+		HighWater   int
+		GracePeriod time.Duration
+	},
+) (host.Host, *dht.IpfsDHT, error) {
+	filterMasks := make([]*net.IPNet, 0, len(addrFilters))
+	for _, mask := range addrFilters {
+		f, err := maskfilter.NewMask(mask)
+		if err != nil {
+			return nil, nil, err
+		}
+		filterMasks = append(filterMasks, f)
+	}
+
+	opts := []libp2p.Option{
+		libp2p.Identity(pk),
+		libp2p.ListenAddrs(listenAddrs...),
+		libp2p.Peerstore(ps),
+		libp2p.FilterAddresses(filterMasks...),
+		libp2p.AddrsFactory(addrsFactory(announceAddrs, noAnnounceAddrs)),
+	}
+	if connMgr.HighWater > 0 {
+		cm, err := connmgr.NewConnManager(connMgr.LowWater, connMgr.HighWater, connmgr.WithGracePeriod(connMgr.GracePeriod))
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, libp2p.ConnectionManager(cm))
+	}
+	if len(secret) > 0 {
+		protector, err := pnet.NewProtector(bytes.NewReader(secret))
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, libp2p.PrivateNetwork(protector))
+	}
+
+	ht, err := libp2p.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dt, err := dht.New(ctx, ht, dht.Datastore(ds), dht.Validator(validator))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ht, dt, nil
+}
+
+// addrsFactory returns an AddrsFactory that overrides advertised addresses
+// with announceAddrs when set, and always strips noAnnounceAddrs.
+func addrsFactory(announceAddrs, noAnnounceAddrs []multiaddr.Multiaddr) func([]multiaddr.Multiaddr) []multiaddr.Multiaddr {
+	return func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+		if len(announceAddrs) > 0 {
+			addrs = announceAddrs
+		}
+		if len(noAnnounceAddrs) == 0 {
+			return addrs
+		}
+		filtered := make([]multiaddr.Multiaddr, 0, len(addrs))
+		for _, addr := range addrs {
+			excluded := false
+			for _, no := range noAnnounceAddrs {
+				if addr.Equal(no) {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				filtered = append(filtered, addr)
+			}
+		}
+		return filtered
+	}
+}
+
+// DefaultBootstrapPeers returns the standard public IPFS bootstrap peers,
+// the list DefaultBootstrap connects to.
+func DefaultBootstrapPeers() []peer.AddrInfo {
+	peers := make([]peer.AddrInfo, 0, len(defaultBootstrapAddrs))
+	for _, addr := range defaultBootstrapAddrs {
+		maddr, err := multiaddr.NewMultiaddr(addr)
+		if err != nil {
+			continue
+		}
+		pi, err := peer.AddrInfoFromP2pAddr(maddr)
+		if err != nil {
+			continue
+		}
+		peers = append(peers, *pi)
+	}
+	return peers
+}
@@ -0,0 +1,83 @@
+package rtns
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/RTradeLtd/grpc/krab"
+	kaas "github.com/RTradeLtd/kaas/v2"
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+// RKeystore is a thin wrapper around a remote krab keystore, used to fetch
+// and manage the private keys rtns publishes IPNS records with.
+type RKeystore struct {
+	ctx context.Context
+	kb  *kaas.Client
+}
+
+// NewRKeystore returns an RKeystore backed by kb.
+func NewRKeystore(ctx context.Context, kb *kaas.Client) *RKeystore {
+	return &RKeystore{ctx: ctx, kb: kb}
+}
+
+// Has reports whether the keystore has a private key named name.
+func (rk *RKeystore) Has(name string) (bool, error) {
+	resp, err := rk.kb.HasPrivateKey(rk.ctx, &pb.Request{Name: name})
+	if err != nil {
+		return false, err
+	}
+	return resp != nil && resp.Status == "OK", nil
+}
+
+// Put stores pk in the keystore under name.
+func (rk *RKeystore) Put(name string, pk ci.PrivKey) error {
+	if pk == nil {
+		return fmt.Errorf("private key cannot be nil")
+	}
+	pkBytes, err := ci.MarshalPrivateKey(pk)
+	if err != nil {
+		return err
+	}
+	resp, err := rk.kb.PutPrivateKey(rk.ctx, &pb.Request{Name: name, PrivateKey: pkBytes})
+	if err != nil {
+		return err
+	}
+	if resp == nil || resp.Status != "OK" {
+		return fmt.Errorf("failed to store key %q", name)
+	}
+	return nil
+}
+
+// Get retrieves the private key named name.
+func (rk *RKeystore) Get(name string) (ci.PrivKey, error) {
+	resp, err := rk.kb.GetPrivateKey(rk.ctx, &pb.Request{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return ci.UnmarshalPrivateKey(resp.PrivateKey)
+}
+
+// Delete removes the private key named name.
+func (rk *RKeystore) Delete(name string) error {
+	resp, err := rk.kb.RemovePrivateKey(rk.ctx, &pb.Request{Name: name})
+	if err != nil {
+		return err
+	}
+	if resp == nil || resp.Status != "OK" {
+		return fmt.Errorf("failed to delete key %q", name)
+	}
+	return nil
+}
+
+// List returns the names of every key in the keystore.
+func (rk *RKeystore) List() ([]string, error) {
+	resp, err := rk.kb.ListPrivateKeys(rk.ctx, &pb.Request{})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.Status != "OK" {
+		return nil, fmt.Errorf("failed to list keys")
+	}
+	return resp.Names, nil
+}
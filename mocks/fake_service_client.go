@@ -0,0 +1,114 @@
+// Package mocks provides a hand-rolled stand-in for the krab ServiceClient,
+// letting tests script per-call responses without a real krab server.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/RTradeLtd/grpc/krab"
+	"google.golang.org/grpc"
+)
+
+type stubbedResponse struct {
+	resp *pb.Response
+	err  error
+}
+
+// callStub records how many times an RPC has been invoked and lets a test
+// script a response for a specific call index via ReturnsOnCall.
+type callStub struct {
+	mu    sync.Mutex
+	calls int
+	stubs map[int]stubbedResponse
+}
+
+func (c *callStub) record() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.calls
+	c.calls++
+	return i
+}
+
+func (c *callStub) returnsOnCall(i int, resp *pb.Response, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stubs == nil {
+		c.stubs = make(map[int]stubbedResponse)
+	}
+	c.stubs[i] = stubbedResponse{resp: resp, err: err}
+}
+
+func (c *callStub) resultFor(i int) (*pb.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stubs[i]
+	return s.resp, s.err
+}
+
+// FakeServiceClient implements pb.ServiceClient. Every RPC is unstubbed by
+// default, returning a nil *pb.Response and a nil error; use the matching
+// <Method>ReturnsOnCall to script a response for a specific call index.
+type FakeServiceClient struct {
+	getPrivateKey    callStub
+	hasPrivateKey    callStub
+	putPrivateKey    callStub
+	removePrivateKey callStub
+	listPrivateKeys  callStub
+}
+
+// GetPrivateKeyReturnsOnCall scripts the response returned by the i'th call
+// to GetPrivateKey.
+func (f *FakeServiceClient) GetPrivateKeyReturnsOnCall(i int, resp *pb.Response, err error) {
+	f.getPrivateKey.returnsOnCall(i, resp, err)
+}
+
+// GetPrivateKey implements pb.ServiceClient.
+func (f *FakeServiceClient) GetPrivateKey(ctx context.Context, in *pb.Request, opts ...grpc.CallOption) (*pb.Response, error) {
+	return f.getPrivateKey.resultFor(f.getPrivateKey.record())
+}
+
+// HasPrivateKeyReturnsOnCall scripts the response returned by the i'th call
+// to HasPrivateKey.
+func (f *FakeServiceClient) HasPrivateKeyReturnsOnCall(i int, resp *pb.Response, err error) {
+	f.hasPrivateKey.returnsOnCall(i, resp, err)
+}
+
+// HasPrivateKey implements pb.ServiceClient.
+func (f *FakeServiceClient) HasPrivateKey(ctx context.Context, in *pb.Request, opts ...grpc.CallOption) (*pb.Response, error) {
+	return f.hasPrivateKey.resultFor(f.hasPrivateKey.record())
+}
+
+// PutPrivateKeyReturnsOnCall scripts the response returned by the i'th call
+// to PutPrivateKey.
+func (f *FakeServiceClient) PutPrivateKeyReturnsOnCall(i int, resp *pb.Response, err error) {
+	f.putPrivateKey.returnsOnCall(i, resp, err)
+}
+
+// PutPrivateKey implements pb.ServiceClient.
+func (f *FakeServiceClient) PutPrivateKey(ctx context.Context, in *pb.Request, opts ...grpc.CallOption) (*pb.Response, error) {
+	return f.putPrivateKey.resultFor(f.putPrivateKey.record())
+}
+
+// RemovePrivateKeyReturnsOnCall scripts the response returned by the i'th
+// call to RemovePrivateKey.
+func (f *FakeServiceClient) RemovePrivateKeyReturnsOnCall(i int, resp *pb.Response, err error) {
+	f.removePrivateKey.returnsOnCall(i, resp, err)
+}
+
+// RemovePrivateKey implements pb.ServiceClient.
+func (f *FakeServiceClient) RemovePrivateKey(ctx context.Context, in *pb.Request, opts ...grpc.CallOption) (*pb.Response, error) {
+	return f.removePrivateKey.resultFor(f.removePrivateKey.record())
+}
+
+// ListPrivateKeysReturnsOnCall scripts the response returned by the i'th
+// call to ListPrivateKeys.
+func (f *FakeServiceClient) ListPrivateKeysReturnsOnCall(i int, resp *pb.Response, err error) {
+	f.listPrivateKeys.returnsOnCall(i, resp, err)
+}
+
+// ListPrivateKeys implements pb.ServiceClient.
+func (f *FakeServiceClient) ListPrivateKeys(ctx context.Context, in *pb.Request, opts ...grpc.CallOption) (*pb.Response, error) {
+	return f.listPrivateKeys.resultFor(f.listPrivateKeys.record())
+}
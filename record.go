@@ -0,0 +1,105 @@
+package rtns
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	proto "github.com/gogo/protobuf/proto"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-ipfs/namesys"
+	ipns "github.com/ipfs/go-ipns"
+	pb "github.com/ipfs/go-ipns/pb"
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+// DefaultRecordTTL is written into a record's TTL field when
+// Config.DefaultTTL is left unset.
+const DefaultRecordTTL = 1 * time.Minute
+
+// loadEntry returns the last IPNS entry published locally for id, or
+// errNoEntry if nothing has been published from this node yet.
+func (r *rtns) loadEntry(id peer.ID) (*pb.IpnsEntry, error) {
+	raw, err := r.ds.Get(namesys.IpnsDsKey(id))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, errNoEntry
+		}
+		return nil, err
+	}
+	var entry pb.IpnsEntry
+	if err := proto.Unmarshal(raw, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// nextSequence returns the sequence number the next record for id should
+// carry: one past whatever we last published, or 0 for a brand new key.
+func (r *rtns) nextSequence(id peer.ID) (uint64, error) {
+	prev, err := r.loadEntry(id)
+	if err == errNoEntry {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return prev.GetSequence() + 1, nil
+}
+
+// buildRecord signs a new IPNS entry for value, honoring Config.DefaultTTL,
+// and embeds pk's public key when it can't be recovered from the peer ID
+// alone (eg. ECDSA and large RSA keys). go-ipns's Create always emits both
+// the V1 and V2 signatures plus the CBOR Data field, so there is no format
+// knob to thread through here.
+func (r *rtns) buildRecord(pk ci.PrivKey, value string, seq uint64, eol time.Time) (*pb.IpnsEntry, error) {
+	ttl := r.cfg.DefaultTTL
+	if ttl == 0 {
+		ttl = DefaultRecordTTL
+	}
+	entry, err := ipns.Create(pk, []byte(value), seq, eol, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if err := ipns.EmbedPublicKey(pk.GetPublic(), entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// publishRecord signs and stores a new record for pk, writing it to both the
+// local datastore (so republishEntry can find it again after a restart) and
+// the DHT.
+func (r *rtns) publishRecord(ctx context.Context, pk ci.PrivKey, value string, eol time.Time) error {
+	id, err := peer.IDFromPrivateKey(pk)
+	if err != nil {
+		return err
+	}
+	seq, err := r.nextSequence(id)
+	if err != nil {
+		return err
+	}
+	entry, err := r.buildRecord(pk, value, seq, eol)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := r.ds.Put(namesys.IpnsDsKey(id), data); err != nil {
+		return err
+	}
+	if err := r.d.PutValue(ctx, ipns.RecordKey(id), data); err != nil {
+		return err
+	}
+
+	r.announcer.queue(ipns.RecordKey(id), data)
+	go func() {
+		if err := r.announcer.flush(r.ctx); err != nil {
+			fmt.Println("error flushing direct announce records:", err.Error())
+		}
+	}()
+	return nil
+}
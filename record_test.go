@@ -0,0 +1,41 @@
+package rtns
+
+import (
+	"testing"
+	"time"
+
+	proto "github.com/gogo/protobuf/proto"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	ipns "github.com/ipfs/go-ipns"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-peerstore/pstoremem"
+)
+
+func Test_BuildRecord_ValidatesWithStockValidator(t *testing.T) {
+	pk := newPK(t)
+	ps := pstoremem.NewPeerstore()
+	r := &rtns{
+		ds: dssync.MutexWrap(datastore.NewMapDatastore()),
+		ps: ps,
+	}
+
+	id, err := peer.IDFromPrivateKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := r.buildRecord(pk, ipfsPath1, 0, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := ipns.Validator{KeyBook: ps}
+	if err := v.Validate(string(ipns.RecordKey(id)), data); err != nil {
+		t.Fatal("record failed stock validation:", err.Error())
+	}
+}
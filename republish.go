@@ -0,0 +1,122 @@
+package rtns
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ipns "github.com/ipfs/go-ipns"
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+// RecordLifetime is the validity window Publish gives a record, and the
+// minimum left on a republished one: a previous EOL sooner than
+// now+RecordLifetime is pushed out to that, a later one is kept.
+const RecordLifetime = 24 * time.Hour
+
+// RepublishInterval controls how often startRepublisher wakes up to refresh
+// locally-known IPNS records.
+const RepublishInterval = 4 * time.Hour
+
+var (
+	// errNoRecordsPublisher is returned by republishEntries when we have no
+	// locally-cached keys to republish on behalf of.
+	errNoRecordsPublisher = errors.New("no records available to republish")
+	// errNoEntry is returned by republishEntry when the datastore has no
+	// locally-written IPNS entry for the given key, eg. a key that was only
+	// ever cached but never actually published from this node.
+	errNoEntry = errors.New("no local ipns entry found for key")
+)
+
+// startRepublisher periodically refreshes every locally-known IPNS record so
+// that its EOL never lapses while rtns is running. It first rehydrates the
+// cache from the keystore's full list of known keys, so a persistent
+// Config.Datastore's records get picked back up after a process restart
+// even though the in-memory cache itself starts out empty.
+func (r *rtns) startRepublisher() {
+	if err := r.loadKnownKeys(); err != nil {
+		fmt.Println("error loading known keys for republisher:", err.Error())
+	}
+
+	ticker := time.NewTicker(RepublishInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.republishEntries(); err != nil && err != errNoRecordsPublisher {
+				fmt.Println("error republishing entries:", err.Error())
+			}
+		}
+	}
+}
+
+// loadKnownKeys seeds the cache with every key name the keystore knows
+// about, so republishEntries has something to work from even before any
+// Publish call has happened in this process.
+func (r *rtns) loadKnownKeys() error {
+	names, err := r.keys.List()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		r.cache.Set(name)
+	}
+	return nil
+}
+
+// republishEntries walks every key we know about locally and republishes its
+// IPNS record, preserving the previous value, advancing the sequence number
+// past the previous one and only ever extending (never shortening) its EOL.
+// A single key failing (a stale or rotated key, a missing local entry) is
+// skipped rather than aborting the rest of the batch; every failure except a
+// missing local entry is logged.
+func (r *rtns) republishEntries() error {
+	keyIDs := r.cache.List()
+	if len(keyIDs) == 0 {
+		return errNoRecordsPublisher
+	}
+	for _, keyID := range keyIDs {
+		priv, err := r.keys.Get(keyID)
+		if err != nil {
+			fmt.Println("error loading key for republish, skipping:", keyID, err.Error())
+			continue
+		}
+		id, err := peer.IDFromPrivateKey(priv)
+		if err != nil {
+			fmt.Println("error deriving peer ID for republish, skipping:", keyID, err.Error())
+			continue
+		}
+		if err := r.republishEntry(id, priv); err != nil {
+			if err == errNoEntry {
+				continue
+			}
+			fmt.Println("error republishing entry, skipping:", keyID, err.Error())
+			continue
+		}
+	}
+	return nil
+}
+
+// republishEntry re-signs the locally stored IPNS record for id, preserving
+// its previous value and monotonically increasing its sequence number. The
+// record's EOL is only ever extended, never shortened: we republish with
+// max(prevEol, now+RecordLifetime).
+func (r *rtns) republishEntry(id peer.ID, priv ci.PrivKey) error {
+	entry, err := r.loadEntry(id)
+	if err != nil {
+		return err
+	}
+
+	eol, err := ipns.GetEOL(entry)
+	if err != nil {
+		return err
+	}
+	if min := time.Now().Add(RecordLifetime); min.After(eol) {
+		eol = min
+	}
+
+	return r.PublishWithEOL(r.ctx, priv, eol, false, id.Pretty(), string(entry.Value))
+}
@@ -0,0 +1,126 @@
+package rtns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	nsopts "github.com/ipfs/interface-go-ipfs-core/options/namesys"
+)
+
+// ResolveOptions configures how Resolve and ResolveAsync look up a name.
+type ResolveOptions struct {
+	Depth          uint
+	DhtRecordCount uint
+	DhtTimeout     time.Duration
+}
+
+// ResolveOption mutates a ResolveOptions. These mirror namesys's own
+// resolution options so callers don't need to reach past the Service
+// interface to tune a lookup.
+type ResolveOption func(*ResolveOptions)
+
+// ResolveWithDepth limits how many /ipns/ indirections Resolve will follow
+// before giving up.
+func ResolveWithDepth(depth uint) ResolveOption {
+	return func(o *ResolveOptions) { o.Depth = depth }
+}
+
+// ResolveWithDhtRecordCount sets how many DHT records must agree before a
+// name is considered resolved.
+func ResolveWithDhtRecordCount(count uint) ResolveOption {
+	return func(o *ResolveOptions) { o.DhtRecordCount = count }
+}
+
+// ResolveWithDhtTimeout bounds how long Resolve will wait on the DHT before
+// falling back to DNSLink.
+func ResolveWithDhtTimeout(timeout time.Duration) ResolveOption {
+	return func(o *ResolveOptions) { o.DhtTimeout = timeout }
+}
+
+func (o ResolveOptions) nsOpts() []nsopts.ResolveOpt {
+	var out []nsopts.ResolveOpt
+	if o.Depth != 0 {
+		out = append(out, nsopts.Depth(o.Depth))
+	}
+	if o.DhtRecordCount != 0 {
+		out = append(out, nsopts.DhtRecordCount(o.DhtRecordCount))
+	}
+	if o.DhtTimeout != 0 {
+		out = append(out, nsopts.DhtTimeout(o.DhtTimeout))
+	}
+	return out
+}
+
+// ResolveResult is delivered on the channel returned by ResolveAsync.
+type ResolveResult struct {
+	Path string
+	Err  error
+}
+
+// Resolve resolves name - an /ipns/<id> path, a bare peer ID, or a domain
+// with a _dnslink TXT record - to the path it currently points at. DNSLink is
+// only consulted when IPNS resolution fails, so a name that happens to
+// collide with both never silently picks the wrong one.
+func (r *rtns) Resolve(ctx context.Context, name string, opts ...ResolveOption) (string, error) {
+	var o ResolveOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p, err := r.ns.Resolve(ctx, name, o.nsOpts()...)
+	if err == nil {
+		return p.String(), nil
+	}
+
+	dnslinkPath, dnslinkErr := resolveDNSLink(ctx, name)
+	if dnslinkErr != nil {
+		return "", err
+	}
+	return dnslinkPath, nil
+}
+
+// ResolveAsync is the streaming counterpart to Resolve, yielding each
+// resolved path as the namesys confirms it rather than waiting for a single
+// answer. DNSLink fallback only fires if namesys's resolution stream closes
+// having produced nothing at all.
+func (r *rtns) ResolveAsync(ctx context.Context, name string, opts ...ResolveOption) <-chan ResolveResult {
+	var o ResolveOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	out := make(chan ResolveResult)
+	go func() {
+		defer close(out)
+		var resolved bool
+		for res := range r.ns.ResolveAsync(ctx, name, o.nsOpts()...) {
+			resolved = true
+			out <- ResolveResult{Path: res.Path.String(), Err: res.Err}
+		}
+		if resolved {
+			return
+		}
+		dnslinkPath, err := resolveDNSLink(ctx, name)
+		out <- ResolveResult{Path: dnslinkPath, Err: err}
+	}()
+	return out
+}
+
+// resolveDNSLink looks up a DNSLink TXT record (_dnslink.<name>), per
+// https://docs.ipfs.tech/concepts/dnslink/.
+func resolveDNSLink(ctx context.Context, name string) (string, error) {
+	host := strings.TrimPrefix(strings.TrimPrefix(name, "/ipns/"), "/ipfs/")
+	records, err := net.DefaultResolver.LookupTXT(ctx, "_dnslink."+host)
+	if err != nil {
+		return "", err
+	}
+	for _, rec := range records {
+		if strings.HasPrefix(rec, "dnslink=") {
+			return strings.TrimPrefix(rec, "dnslink="), nil
+		}
+	}
+	return "", fmt.Errorf("no dnslink record found for %s", host)
+}